@@ -6,13 +6,30 @@ import (
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"net/url"
 	"os"
+	"path/filepath"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	// Third-party
+	"github.com/BurntSushi/toml"
 	goutils "github.com/marksost/go-utils"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// Format represents a supported configuration file format, determined by a file's extension
+type Format string
+
+const (
+	// FormatJSON denotes a JSON-encoded configuration file
+	FormatJSON Format = "json"
+	// FormatYAML denotes a YAML-encoded configuration file
+	FormatYAML Format = "yaml"
+	// FormatTOML denotes a TOML-encoded configuration file
+	FormatTOML Format = "toml"
 )
 
 var (
@@ -22,52 +39,71 @@ var (
 	// ConfigLocation is an environment variable with path to a config file
 	// NOTE: This can be changed from outside this package before calling `InitializeConfig`
 	ConfigLocation = EnvPrefix + "CONFIG"
+	// EnvVar is an environment variable holding the name of the active environment (e.g. "production"),
+	// used to discover and apply config file overlays (e.g. `config.yaml` + `config.production.yaml`)
+	// NOTE: This can be changed from outside this package before calling `InitializeConfig`
+	EnvVar = EnvPrefix + "ENV"
 )
 
 // InitializeConfig is the main entrypoint to this package and takes in what is presumed to be
 // a configuration struct with proper tags. It attempts to set up default values for each property,
 // based on a `default` tag on the property. It then attempts to read in a configuration file
 // based on a location retrieved from an environment variable (set with the above `ConfigLocation`) variable.
-// This configuration file must be proper JSON and keys should map to `json` tags on the struct properties.
+// This configuration file must be JSON, YAML, or TOML (determined by it's extension) and keys should map
+// to `json` tags on the struct properties. If an environment name is present under `EnvVar`, a matching
+// overlay file (e.g. `config.production.yaml` alongside `config.yaml`) is applied on top of it.
 // NOTE: You may want to alter the value of that variable to be what your environment uses
 // It will then attempt to read in environment variables to each struct property, using a concatenation
 // of the `EnvPrefix` variable above and the value of an `env` tag for each property.
 // Finally, it will parse command-line flags, using the unprefixed, lowercase version of the `env` tag value
 // for each property
-func InitializeConfig(c interface{}) {
+// NOTE: Returns a `*ConfigError` aggregating every field-level failure encountered along the
+// way. What happens with that error (returned as-is, logged and `os.Exit`'d, or panicked) is
+// governed by `ErrorHandlingMode`, mirroring `flag.ErrorHandling`
+func InitializeConfig(c interface{}) error {
+	var errs []FieldError
+
 	// Set up default values for configuration struct
-	setDefaults(c)
+	errs = append(errs, handleDefaults(reflect.ValueOf(c), "")...)
 
 	// Read in config file (if it exists) and set values on configuration struct
 	setFromConfigFile(c)
 
 	// Set environment variable-based values on configuration struct
-	setFromEnvironment(c)
+	errs = append(errs, setFromEnvironment(c)...)
 
 	// Parse command-line flags
 	flag.Parse()
+
+	// Check `required` and `validate` tags now that every source has had a chance to set a value
+	errs = append(errs, validateFields(reflect.ValueOf(c), "")...)
+
+	return handleConfigErrors(errs)
 }
 
 // setDefaults attempts to set default values for configuration properties
 // based on a `default` tag assigned to each property
-func setDefaults(c interface{}) {
+func setDefaults(c interface{}) []FieldError {
 	// Reflect value and pass to internal method
-	handleDefaults(reflect.ValueOf(c))
+	return handleDefaults(reflect.ValueOf(c), "")
 }
 
 // handleDefaults loops through a reflected value's fields based on their "kind",
 // checks for a corresponding `default` tag and if found, sets it's value on the config
 // NOTE: Abstracted from `setDefaults` to allow for struct recursion
-func handleDefaults(v reflect.Value) {
+func handleDefaults(v reflect.Value, path string) []FieldError {
 	// Reflect indirectly to allow field looping
 	val := reflect.Indirect(v)
 
+	var errs []FieldError
+
 	// Loop through fields
 	for i := 0; i < val.NumField(); i++ {
 		// Store field, kind, and tag value
 		field := val.Field(i)
 		kind := val.Field(i).Kind()
 		tag := val.Type().Field(i).Tag.Get("default")
+		name := fieldPath(path, val.Type().Field(i).Name)
 
 		// TO-DO: Logging?
 
@@ -76,6 +112,24 @@ func handleDefaults(v reflect.Value) {
 			continue
 		}
 
+		// A `${OTHER_VAR}`-style reference in the default value is expanded before it's parsed
+		if val.Type().Field(i).Tag.Get("expand") == "true" {
+			tag = os.ExpandEnv(tag)
+		}
+
+		// A default that doesn't parse as the field's "kind" is recorded as a `FieldError` instead of being set
+		var perr error
+		switch kind {
+		case reflect.Bool:
+			_, perr = strconv.ParseBool(tag)
+		case reflect.Int:
+			_, perr = strconv.ParseInt(tag, 10, 0)
+		}
+		if perr != nil {
+			errs = append(errs, FieldError{Field: name, Tag: "default", Value: tag, Err: perr})
+			continue
+		}
+
 		// Handle field by it's "kind"
 		switch kind {
 		case reflect.Bool:
@@ -86,47 +140,76 @@ func handleDefaults(v reflect.Value) {
 			field.SetString(tag)
 		case reflect.Struct:
 			// Recurse
-			handleDefaults(field.Addr())
+			errs = append(errs, handleDefaults(field.Addr(), name)...)
 		default:
 			// TO-DO: Logging?
 		}
 	}
+
+	return errs
 }
 
-// setFromConfigFile attempts to unmarshal a configuration file's contents
-// from JSON into the config struct, overriding any default values set previously
+// setFromConfigFile attempts to unmarshal a configuration file's contents into the config
+// struct, overriding any default values set previously. The file's format (JSON, YAML, or TOML)
+// is determined by its extension. Once the base file has been applied, any environment-specific
+// overlay files (see `overlayConfigFiles`) are applied on top, in order, so later files win
 func setFromConfigFile(c interface{}) bool {
 	var (
+		applied  bool   // Whether at least one config file was successfully applied
 		contents []byte // Content of config file
 		err      error  // Catch-all error
+		file     string // Config file location, gotten from environment variable
 	)
 
-	// Get config file contents
-	if contents, err = getConfigFileContents(); err != nil {
+	// Allow for environment-level config file location override
+	if file = os.Getenv(ConfigLocation); file == "" {
+		// TO-DO: Logging?
+		return false
+	}
+
+	// Get base config file contents
+	if contents, err = getConfigFileContents(file); err != nil {
 		// TO-DO: Logging?
 		return false
 	}
 
-	// Attempt to unmarshal JSON into config struct
-	if err = json.Unmarshal(contents, &c); err != nil {
+	// Attempt to unmarshal config file into config struct, based on it's format
+	if err = unmarshalConfigFile(file, contents, c); err != nil {
 		// TO-DO: Logging?
 		return false
 	}
 
-	return true
+	applied = true
+
+	// Apply any environment-specific overlay files on top of the base file, in order,
+	// so that later files override earlier ones
+	for _, overlay := range overlayConfigFiles(file) {
+		if contents, err = getConfigFileContents(overlay); err != nil {
+			// TO-DO: Logging?
+			continue
+		}
+
+		if err = unmarshalConfigFile(overlay, contents, c); err != nil {
+			// TO-DO: Logging?
+			continue
+		}
+	}
+
+	// Dereference any `file://`-style (or other registered scheme) values set by the file(s)
+	resolveConfigValues(c)
+
+	return applied
 }
 
-// getConfigFileContents attempts to load a JSON configuration file from disk and
+// getConfigFileContents attempts to load a configuration file from disk and
 // return it's contents if found, or an error if not
-func getConfigFileContents() ([]byte, error) {
+func getConfigFileContents(file string) ([]byte, error) {
 	var (
 		contents []byte // Content of config file
 		err      error  // Catch-all error
-		file     string // Config file location, gotten from environment variable
 	)
 
-	// Allow for environment-level config file location override
-	if file = os.Getenv(ConfigLocation); file == "" {
+	if file == "" {
 		return nil, fmt.Errorf("No valid file path detected under environment variable %s", ConfigLocation)
 	}
 
@@ -141,59 +224,221 @@ func getConfigFileContents() ([]byte, error) {
 	return contents, nil
 }
 
+// configFormat determines a configuration file's format based on it's extension,
+// defaulting to JSON for backwards compatibility
+func configFormat(file string) Format {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+// unmarshalConfigFile decodes a configuration file's contents into the config struct,
+// using the unmarshaller appropriate for it's format. Every field in this package is
+// tagged with `json`, not `yaml`/`toml`, so YAML and TOML are first decoded into a
+// generic representation and re-marshalled to JSON before being applied to the config
+// struct, ensuring all three formats key off the same `json` tags
+func unmarshalConfigFile(file string, contents []byte, c interface{}) error {
+	switch configFormat(file) {
+	case FormatYAML:
+		return unmarshalViaJSON(contents, c, yamlToJSON)
+	case FormatTOML:
+		return unmarshalViaJSON(contents, c, tomlToJSON)
+	default:
+		return json.Unmarshal(contents, &c)
+	}
+}
+
+// unmarshalViaJSON decodes contents using the given converter (which yields JSON), then
+// unmarshals that JSON into the config struct, so YAML/TOML keys resolve against `json` tags
+func unmarshalViaJSON(contents []byte, c interface{}, convert func([]byte) ([]byte, error)) error {
+	jsonContents, err := convert(contents)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonContents, &c)
+}
+
+// yamlToJSON decodes YAML contents into a generic representation and re-marshals it as JSON
+func yamlToJSON(contents []byte) ([]byte, error) {
+	var generic interface{}
+
+	if err := yaml.Unmarshal(contents, &generic); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(convertYAMLMapKeys(generic))
+}
+
+// convertYAMLMapKeys recursively converts the `map[interface{}]interface{}` values produced
+// by `yaml.Unmarshal` into `map[string]interface{}`, which is what `encoding/json` requires
+func convertYAMLMapKeys(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(val))
+		for k, v := range val {
+			m[fmt.Sprintf("%v", k)] = convertYAMLMapKeys(v)
+		}
+		return m
+	case []interface{}:
+		for i, item := range val {
+			val[i] = convertYAMLMapKeys(item)
+		}
+		return val
+	default:
+		return val
+	}
+}
+
+// tomlToJSON decodes TOML contents into a generic representation and re-marshals it as JSON
+func tomlToJSON(contents []byte) ([]byte, error) {
+	var generic interface{}
+
+	if err := toml.Unmarshal(contents, &generic); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(generic)
+}
+
+// overlayConfigFiles discovers environment-specific overlay files for a given base config
+// file (e.g. `config.yaml` => `config.production.yaml`), based on the active environment
+// name found under `EnvVar`. Returns an empty slice when no environment is set or no
+// matching overlay file exists on disk
+func overlayConfigFiles(file string) []string {
+	var (
+		env   string   // Active environment name, gotten from environment variable
+		ext   string   // Base file's extension, including the leading dot
+		files []string // Discovered overlay files
+	)
+
+	// No active environment means no overlays to apply
+	if env = os.Getenv(EnvVar); env == "" {
+		return files
+	}
+
+	ext = filepath.Ext(file)
+	overlay := fmt.Sprintf("%s.%s%s", strings.TrimSuffix(file, ext), env, ext)
+
+	// Only include the overlay if it actually exists on disk
+	if _, err := os.Stat(overlay); err == nil {
+		files = append(files, overlay)
+	}
+
+	return files
+}
+
 // setFromEnvironment attempts to load environment variables matching
 // the config struct's env tags, overriding any default or file-based values set previously
-func setFromEnvironment(c interface{}) {
+// NOTE: When `EnvIterationMode` is enabled, this instead iterates `os.Environ()` directly
+// (see `handleEnvIteration`), which allows setting keys on maps that aren't pre-declared
+func setFromEnvironment(c interface{}) []FieldError {
+	if EnvIterationMode {
+		handleEnvIteration(c)
+		return nil
+	}
+
 	// Reflect value and pass to internal method
-	handleEnvironmentVariables(reflect.ValueOf(c))
+	return handleEnvironmentVariables(reflect.ValueOf(c), "")
 }
 
 // handleEnvironmentVariables loops through a reflected value's fields by their "kind",
 // checks for a corresponding environment variable and if found, sets it
 // both on the config and as a flag (when allowed)
 // NOTE: Abstracted from `setFromEnvironment` to allow for struct recursion
-func handleEnvironmentVariables(v reflect.Value) {
+func handleEnvironmentVariables(v reflect.Value, path string) []FieldError {
 	// Reflect indirectly to allow field looping
 	val := reflect.Indirect(v)
 
+	var errs []FieldError
+
 	// Loop through fields
 	for i := 0; i < val.NumField(); i++ {
 		// Store kind, env tag value, flag name, and OS value
 		kind := val.Field(i).Kind()
 		tag := EnvPrefix + val.Type().Field(i).Tag.Get("env")
 		flagName := formFlagName(tag)
+		name := fieldPath(path, val.Type().Field(i).Name)
 		// NOTE: Enforces upper-case env variables
 		env := os.Getenv(strings.ToUpper(tag))
 
+		// A `${OTHER_VAR}`-style reference in the environment value is expanded before it's parsed
+		if env != "" && val.Type().Field(i).Tag.Get("expand") == "true" {
+			env = os.ExpandEnv(env)
+		}
+
+		// A `file://`-style (or other registered scheme) reference is dereferenced before
+		// it's parsed, letting secrets be mounted on disk instead of baked into the env var
+		if env != "" {
+			resolved, rerr := resolveValue(env)
+			if rerr != nil {
+				errs = append(errs, FieldError{Field: name, Tag: "env", Value: env, Err: rerr})
+				continue
+			}
+
+			env = resolved
+		}
+
 		// TO-DO: Logging?
 
-		// Handle field by it's "kind"
+		// Handle field by it's "kind", recording a `FieldError` for any that fail to parse
+		var err error
+
 		switch kind {
 		case reflect.Bool:
-			handleBoolEnvironmentVariable(val, i, flagName, env)
-		case reflect.Int:
-			handleIntEnvironmentVariable(val, i, flagName, env)
+			err = handleBoolEnvironmentVariable(val, i, flagName, env)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			err = handleIntEnvironmentVariable(val, i, flagName, env)
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			err = handleUintEnvironmentVariable(val, i, flagName, env)
+		case reflect.Float32, reflect.Float64:
+			err = handleFloatEnvironmentVariable(val, i, flagName, env)
 		case reflect.String:
-			handleStringEnvironmentVariable(val, i, flagName, env)
+			err = handleStringEnvironmentVariable(val, i, flagName, env)
+		case reflect.Slice:
+			err = handleSliceEnvironmentVariable(val, i, flagName, env)
+		case reflect.Map:
+			err = handleMapEnvironmentVariable(val, i, flagName, env)
 		case reflect.Struct:
-			// Recurse
-			handleEnvironmentVariables(val.Field(i).Addr())
+			switch val.Field(i).Interface().(type) {
+			case time.Time:
+				err = handleTimeEnvironmentVariable(val, i, flagName, env)
+			case url.URL:
+				err = handleURLEnvironmentVariable(val, i, flagName, env)
+			default:
+				// Recurse
+				errs = append(errs, handleEnvironmentVariables(val.Field(i).Addr(), name)...)
+			}
 		default:
 			// TO-DO: Logging?
 		}
+
+		if err != nil {
+			errs = append(errs, FieldError{Field: name, Tag: "env", Value: env, Err: err})
+		}
 	}
+
+	return errs
 }
 
 // handleBoolEnvironmentVariable handles fields with a "kind" of bool
 // Sets a field's value as well as a flag (when allowed)
-func handleBoolEnvironmentVariable(v reflect.Value, i int, flagName string, env string) {
+func handleBoolEnvironmentVariable(v reflect.Value, i int, flagName string, env string) error {
 	// Store field
 	field := v.Field(i)
+	var err error
 
 	// Handle non-empty environment variable
 	if env != "" {
-		parsed, _ := strconv.ParseBool(env)
-		field.SetBool(parsed)
+		var parsed bool
+		if parsed, err = strconv.ParseBool(env); err == nil {
+			field.SetBool(parsed)
+		}
 	}
 
 	// If allowed, set a flag
@@ -204,18 +449,30 @@ func handleBoolEnvironmentVariable(v reflect.Value, i int, flagName string, env
 		ptr := field.Addr().Interface().(*bool)
 		flag.BoolVar(ptr, flagName, field.Bool(), "")
 	}
+
+	return err
 }
 
-// handleIntEnvironmentVariable handles fields with a "kind" of int
+// handleIntEnvironmentVariable handles fields with a signed integer "kind", of any bit size,
+// as well as the `time.Duration` type (which is itself backed by an int64)
 // Sets a field's value as well as a flag (when allowed)
-func handleIntEnvironmentVariable(v reflect.Value, i int, flagName string, env string) {
+func handleIntEnvironmentVariable(v reflect.Value, i int, flagName string, env string) error {
 	// Store field
 	field := v.Field(i)
 
+	// `time.Duration` is a distinct type from a "plain" int64 and gets its own parsing rules
+	if _, ok := field.Interface().(time.Duration); ok {
+		return handleDurationEnvironmentVariable(v, i, flagName, env)
+	}
+
+	var err error
+
 	// Handle non-empty environment variable
 	if env != "" {
-		parsed, _ := strconv.ParseInt(env, 10, 0)
-		field.SetInt(int64(parsed))
+		var parsed int64
+		if parsed, err = strconv.ParseInt(env, 10, field.Type().Bits()); err == nil {
+			field.SetInt(parsed)
+		}
 	}
 
 	// If allowed, set a flag
@@ -223,14 +480,24 @@ func handleIntEnvironmentVariable(v reflect.Value, i int, flagName string, env s
 	// and thus reflect's Interface method can return it's value
 	// See https://golang.org/pkg/reflect/#StructField for more information
 	if flag.Lookup(flagName) == nil && v.Type().Field(i).PkgPath == "" {
-		ptr := field.Addr().Interface().(*int)
-		flag.IntVar(ptr, flagName, int(field.Int()), "")
+		switch field.Kind() {
+		case reflect.Int:
+			ptr := field.Addr().Interface().(*int)
+			flag.IntVar(ptr, flagName, int(field.Int()), "")
+		case reflect.Int64:
+			ptr := field.Addr().Interface().(*int64)
+			flag.Int64Var(ptr, flagName, field.Int(), "")
+		default:
+			flag.Var(newFieldValue(field, parseInt), flagName, "")
+		}
 	}
+
+	return err
 }
 
 // handleStringEnvironmentVariable handles fields with a "kind" of string
 // Sets a field's value as well as a flag (when allowed)
-func handleStringEnvironmentVariable(v reflect.Value, i int, flagName string, env string) {
+func handleStringEnvironmentVariable(v reflect.Value, i int, flagName string, env string) error {
 	// Store field
 	field := v.Field(i)
 
@@ -247,6 +514,8 @@ func handleStringEnvironmentVariable(v reflect.Value, i int, flagName string, en
 		ptr := field.Addr().Interface().(*string)
 		flag.StringVar(ptr, flagName, field.String(), "")
 	}
+
+	return nil
 }
 
 // formFlagName converts a field's tag corresponding to an environment variable
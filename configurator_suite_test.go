@@ -4,7 +4,9 @@ package configurator
 import (
 	// Standard lib
 	"io/ioutil"
+	"net/url"
 	"testing"
+	"time"
 
 	// Third-party
 	. "github.com/onsi/ginkgo"
@@ -15,13 +17,25 @@ import (
 type (
 	// Struct to use for testing configurator methods
 	TestConfig struct {
-		Foo         string            `default:"foo" json:"foo" env:"ENV_FOO"`
-		FooEmpty    string            `default:"" json:"" env:""`
-		Bar         int               `default:"1234" json:"bar" env:"ENV_BAR"`
-		BarEmpty    int               `default:"" json:"" env:""`
-		Baz         bool              `default:"true" json:"baz" env:"ENV_BAZ"`
-		BazEmpty    bool              `default:""  json:"" env:""`
-		Unsupported map[string]string `default:"doesnt-matter" json:"doesnt-matter" env:"DOESNT_MATTER"`
+		Foo         string                 `default:"foo" json:"foo" env:"ENV_FOO"`
+		FooEmpty    string                 `default:"" json:"" env:""`
+		Bar         int                    `default:"1234" json:"bar" env:"ENV_BAR"`
+		BarEmpty    int                    `default:"" json:"" env:""`
+		Baz         bool                   `default:"true" json:"baz" env:"ENV_BAZ"`
+		BazEmpty    bool                   `default:""  json:"" env:""`
+		Int8        int8                   `default:"" json:"int8" env:"ENV_INT8"`
+		Uint        uint                   `default:"" json:"uint" env:"ENV_UINT"`
+		Float64     float64                `default:"" json:"float64" env:"ENV_FLOAT64"`
+		Duration    time.Duration          `default:"" json:"duration" env:"ENV_DURATION"`
+		Slice       []string               `default:"" json:"slice" env:"ENV_SLICE" separator:";"`
+		Map         map[string]string      `default:"" json:"map" env:"ENV_MAP"`
+		Storage     map[string]interface{} `default:"" json:"storage" env:"STORAGE"`
+		Unsupported chan bool              `default:"doesnt-matter" json:"doesnt-matter" env:"DOESNT_MATTER"`
+		Required    string                 `default:"" json:"required" env:"ENV_REQUIRED" required:"true"`
+		Validated   int                    `default:"" json:"validated" env:"ENV_VALIDATED" validate:"min=1;max=10"`
+		Expanded    string                 `default:"${CONFIGURATOR_TEST_EXPAND}" json:"expanded" env:"ENV_EXPANDED" expand:"true"`
+		Secret      string                 `default:"" json:"secret" env:"ENV_SECRET"`
+		URL         url.URL                `default:"" json:"url" env:"ENV_URL" validate:"oneof=http://a.com|http://b.com"`
 		Test        struct {
 			Foo string `default:"test-foo" json:"test-foo" env:"ENV_TEST_FOO"`
 		} `json:"test"`
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path"
 	"reflect"
+	"time"
 
 	// Third-party
 	. "github.com/onsi/ginkgo"
@@ -91,7 +92,7 @@ var _ = Describe("configurator.go", func() {
 		Describe("`handleDefaults` method", func() {
 			It("Sets configuration values based on default values and types", func() {
 				// Call method
-				handleDefaults(reflect.ValueOf(testConfig))
+				handleDefaults(reflect.ValueOf(testConfig), "")
 
 				// Verify values were set
 				Expect(testConfig.Foo).To(Equal("foo"))
@@ -155,6 +156,111 @@ var _ = Describe("configurator.go", func() {
 					Expect(testConfig.Test.Foo).To(Equal("bcde"))
 				})
 			})
+
+			Context("When the configuration file contains valid YAML", func() {
+				BeforeEach(func() {
+					// Set config env var
+					os.Setenv(ConfigLocation, path.Join("test/data/valid-config.yaml"))
+				})
+
+				It("Reads in the configuration, sets values, and returns true", func() {
+					// Verify return value
+					Expect(setFromConfigFile(testConfig)).To(BeTrue())
+
+					// Verify values were set
+					Expect(testConfig.Foo).To(Equal("abcd"))
+					Expect(testConfig.Test.Foo).To(Equal("bcde"))
+				})
+			})
+
+			Context("When the configuration file contains valid TOML", func() {
+				BeforeEach(func() {
+					// Set config env var
+					os.Setenv(ConfigLocation, path.Join("test/data/valid-config.toml"))
+				})
+
+				It("Reads in the configuration, sets values, and returns true", func() {
+					// Verify return value
+					Expect(setFromConfigFile(testConfig)).To(BeTrue())
+
+					// Verify values were set
+					Expect(testConfig.Foo).To(Equal("abcd"))
+					Expect(testConfig.Test.Foo).To(Equal("bcde"))
+				})
+			})
+
+			Context("When an overlay file exists for the active environment", func() {
+				BeforeEach(func() {
+					// Set config env var
+					os.Setenv(ConfigLocation, path.Join("test/data/valid-config.yaml"))
+					os.Setenv(EnvVar, "production")
+				})
+
+				AfterEach(func() {
+					os.Unsetenv(EnvVar)
+				})
+
+				It("Applies the overlay file's values on top of the base file's", func() {
+					// Verify return value
+					Expect(setFromConfigFile(testConfig)).To(BeTrue())
+
+					// Verify the overlay's value won out, while untouched values remain
+					Expect(testConfig.Foo).To(Equal("wxyz"))
+					Expect(testConfig.Test.Foo).To(Equal("bcde"))
+				})
+			})
+		})
+
+		Describe("`configFormat` method", func() {
+			It("Determines a file's format from it's extension, defaulting to JSON", func() {
+				Expect(configFormat("config.yaml")).To(Equal(FormatYAML))
+				Expect(configFormat("config.yml")).To(Equal(FormatYAML))
+				Expect(configFormat("config.toml")).To(Equal(FormatTOML))
+				Expect(configFormat("config.json")).To(Equal(FormatJSON))
+				Expect(configFormat("config")).To(Equal(FormatJSON))
+			})
+		})
+
+		Describe("`overlayConfigFiles` method", func() {
+			Context("When no active environment is set", func() {
+				BeforeEach(func() {
+					os.Unsetenv(EnvVar)
+				})
+
+				It("Returns no overlay files", func() {
+					Expect(overlayConfigFiles(path.Join("test/data/valid-config.yaml"))).To(BeEmpty())
+				})
+			})
+
+			Context("When an active environment is set but no matching overlay file exists", func() {
+				BeforeEach(func() {
+					os.Setenv(EnvVar, "staging")
+				})
+
+				AfterEach(func() {
+					os.Unsetenv(EnvVar)
+				})
+
+				It("Returns no overlay files", func() {
+					Expect(overlayConfigFiles(path.Join("test/data/valid-config.yaml"))).To(BeEmpty())
+				})
+			})
+
+			Context("When an active environment is set and a matching overlay file exists", func() {
+				BeforeEach(func() {
+					os.Setenv(EnvVar, "production")
+				})
+
+				AfterEach(func() {
+					os.Unsetenv(EnvVar)
+				})
+
+				It("Returns the matching overlay file", func() {
+					Expect(overlayConfigFiles(path.Join("test/data/valid-config.yaml"))).To(Equal([]string{
+						path.Join("test/data/valid-config.production.yaml"),
+					}))
+				})
+			})
 		})
 	})
 
@@ -164,29 +270,61 @@ var _ = Describe("configurator.go", func() {
 			os.Setenv(EnvPrefix+"ENV_FOO", "foo")
 			os.Setenv(EnvPrefix+"ENV_BAR", "1234")
 			os.Setenv(EnvPrefix+"ENV_BAZ", "1")
+			os.Setenv(EnvPrefix+"ENV_INT8", "12")
+			os.Setenv(EnvPrefix+"ENV_UINT", "34")
+			os.Setenv(EnvPrefix+"ENV_FLOAT64", "1.5")
+			os.Setenv(EnvPrefix+"ENV_DURATION", "5s")
+			os.Setenv(EnvPrefix+"ENV_SLICE", "a;b;c")
+			os.Setenv(EnvPrefix+"ENV_MAP", "a:1,b:2")
 			os.Setenv(EnvPrefix+"ENV_TEST_FOO", "test-foo")
 		})
 
+		AfterEach(func() {
+			// Unset test environment variables
+			os.Unsetenv(EnvPrefix + "ENV_FOO")
+			os.Unsetenv(EnvPrefix + "ENV_BAR")
+			os.Unsetenv(EnvPrefix + "ENV_BAZ")
+			os.Unsetenv(EnvPrefix + "ENV_INT8")
+			os.Unsetenv(EnvPrefix + "ENV_UINT")
+			os.Unsetenv(EnvPrefix + "ENV_FLOAT64")
+			os.Unsetenv(EnvPrefix + "ENV_DURATION")
+			os.Unsetenv(EnvPrefix + "ENV_SLICE")
+			os.Unsetenv(EnvPrefix + "ENV_MAP")
+			os.Unsetenv(EnvPrefix + "ENV_TEST_FOO")
+		})
+
 		Describe("`handleEnvironmentVariables` method", func() {
 			It("Sets configuration values based on environment variable values and types", func() {
 				// Call method
-				handleEnvironmentVariables(reflect.ValueOf(testConfig))
+				handleEnvironmentVariables(reflect.ValueOf(testConfig), "")
 
 				// Verify values were set
 				Expect(testConfig.Foo).To(Equal("foo"))
 				Expect(testConfig.Bar).To(Equal(1234))
 				Expect(testConfig.Baz).To(BeTrue())
+				Expect(testConfig.Int8).To(Equal(int8(12)))
+				Expect(testConfig.Uint).To(Equal(uint(34)))
+				Expect(testConfig.Float64).To(Equal(1.5))
+				Expect(testConfig.Duration).To(Equal(5 * time.Second))
+				Expect(testConfig.Slice).To(Equal([]string{"a", "b", "c"}))
+				Expect(testConfig.Map).To(Equal(map[string]string{"a": "1", "b": "2"}))
 				Expect(testConfig.Test.Foo).To(Equal("test-foo"))
 			})
 
 			It("Sets flags based on environment variables that set set", func() {
 				// Call method
-				handleEnvironmentVariables(reflect.ValueOf(testConfig))
+				handleEnvironmentVariables(reflect.ValueOf(testConfig), "")
 
 				// Verify flags were set
 				Expect(flag.Lookup("env-foo")).To(Not(BeNil()))
 				Expect(flag.Lookup("env-bar")).To(Not(BeNil()))
 				Expect(flag.Lookup("env-baz")).To(Not(BeNil()))
+				Expect(flag.Lookup("env-int8")).To(Not(BeNil()))
+				Expect(flag.Lookup("env-uint")).To(Not(BeNil()))
+				Expect(flag.Lookup("env-float64")).To(Not(BeNil()))
+				Expect(flag.Lookup("env-duration")).To(Not(BeNil()))
+				Expect(flag.Lookup("env-slice")).To(Not(BeNil()))
+				Expect(flag.Lookup("env-map")).To(Not(BeNil()))
 				Expect(flag.Lookup("env-test-foo")).To(Not(BeNil()))
 			})
 		})
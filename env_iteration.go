@@ -0,0 +1,249 @@
+// Contains an alternative environment-variable processing mode that iterates `os.Environ()`
+// directly instead of walking the config struct, allowing map fields to pick up keys that
+// aren't pre-declared on the struct or in the config file
+package configurator
+
+import (
+	// Standard lib
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// EnvIterationMode switches `setFromEnvironment` from walking the config struct's fields
+// (the default) to iterating `os.Environ()` directly and descending into the struct one
+// path segment at a time. This allows entries in maps whose keys aren't pre-declared in the
+// config file or struct to be set, e.g. `CONFIGURATOR_STORAGE_S3_BUCKET=...` populating
+// `Storage["s3"]["bucket"]` even when "s3" was never listed anywhere
+// NOTE: This can be changed from outside this package before calling `InitializeConfig`
+var EnvIterationMode = false
+
+// handleEnvIteration loops over every environment variable, picks out the ones prefixed with
+// `EnvPrefix`, and walks each one's remaining path (split on "_") into the config struct
+func handleEnvIteration(c interface{}) {
+	// Store prefix to match against, upper-cased to match the same convention used elsewhere
+	prefix := strings.ToUpper(EnvPrefix)
+
+	// Loop through all environment variables
+	for _, kv := range os.Environ() {
+		pair := strings.SplitN(kv, "=", 2)
+		if len(pair) != 2 {
+			continue
+		}
+
+		key, raw := strings.ToUpper(pair[0]), pair[1]
+
+		if !strings.HasPrefix(key, prefix) || raw == "" {
+			continue
+		}
+
+		segments := strings.Split(strings.TrimPrefix(key, prefix), "_")
+
+		// TO-DO: Logging?
+		setEnvPath(reflect.ValueOf(c), segments, raw)
+	}
+}
+
+// setEnvPath descends into a reflected value one or more path segments at a time, locating
+// the field (or allocating the map key) the segments refer to, and sets the raw value once
+// the full path has been consumed. Returns whether a value was actually set
+func setEnvPath(v reflect.Value, segments []string, raw string) bool {
+	// Reflect indirectly to support both pointers and values
+	val := reflect.Indirect(v)
+
+	if len(segments) == 0 || !val.IsValid() {
+		return false
+	}
+
+	switch val.Kind() {
+	case reflect.Struct:
+		idx, consumed := findFieldForSegments(val, segments)
+		if idx < 0 {
+			return false
+		}
+
+		field, remaining := val.Field(idx), segments[consumed:]
+
+		// The matched field's tag (or name) accounted for the whole remaining path,
+		// meaning this field itself is the target: set it directly, rather than recursing
+		// with nothing left to match against
+		if len(remaining) == 0 {
+			return setEnvLeaf(field, raw, val.Type().Field(idx).Tag.Get("separator"))
+		}
+
+		return setEnvPath(field.Addr(), remaining, raw)
+	case reflect.Map:
+		return setEnvMapPath(val, segments, raw)
+	default:
+		// A scalar field can't consume the path segments still left over
+		return false
+	}
+}
+
+// setEnvLeaf sets a raw value directly onto a field that isn't itself a struct or map (those
+// require further path segments to locate a specific value within them). `separator` is used
+// to split a `Slice` field's raw value, the way `handleSliceEnvironmentVariable` does, and
+// defaults to `defaultSeparator` when empty
+func setEnvLeaf(field reflect.Value, raw string, separator string) bool {
+	switch field.Kind() {
+	case reflect.Struct, reflect.Map:
+		return false
+	case reflect.Slice:
+		if separator == "" {
+			separator = defaultSeparator
+		}
+
+		return setSlice(field, raw, separator) == nil
+	default:
+		setScalar(field, field.Type(), raw)
+
+		return true
+	}
+}
+
+// findFieldForSegments finds the struct field whose `env` or `json` tag (or, failing that,
+// name) matches the longest possible prefix of the given path segments, case-insensitively.
+// Returns the field index and how many segments were consumed, or -1 if nothing matched
+func findFieldForSegments(val reflect.Value, segments []string) (int, int) {
+	var (
+		bestIndex    = -1
+		bestConsumed = 0
+	)
+
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Type().Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		for _, tag := range []string{field.Tag.Get("env"), field.Tag.Get("json")} {
+			consumed := matchingSegments(tag, segments)
+			if consumed > bestConsumed {
+				bestIndex, bestConsumed = i, consumed
+			}
+		}
+
+		// Fall back to the field's own name, matching a single segment
+		if bestConsumed == 0 && len(segments) > 0 && strings.EqualFold(field.Name, segments[0]) {
+			bestIndex, bestConsumed = i, 1
+		}
+	}
+
+	return bestIndex, bestConsumed
+}
+
+// matchingSegments reports how many leading path segments, joined with "_", reconstruct
+// the given tag value (case-insensitively, hyphens treated as underscores). Returns 0 when
+// the tag is empty or doesn't match any prefix of the segments
+func matchingSegments(tag string, segments []string) int {
+	if tag == "" {
+		return 0
+	}
+
+	tag = strings.ToUpper(strings.Replace(tag, "-", "_", -1))
+
+	for n := 1; n <= len(segments); n++ {
+		if strings.Join(segments[:n], "_") == tag {
+			return n
+		}
+	}
+
+	return 0
+}
+
+// setEnvMapPath sets a value on a (possibly nil) map field, allocating both the map itself
+// and any intermediate keys as needed. Supports `map[string]T` (for scalar `T`, terminating
+// the path) as well as `map[string]interface{}` and nested `map[string]T` values (allowing
+// further path segments to be consumed as dynamically-created keys)
+func setEnvMapPath(val reflect.Value, segments []string, raw string) bool {
+	if val.IsNil() {
+		val.Set(reflect.MakeMap(val.Type()))
+	}
+
+	var (
+		key      = reflect.ValueOf(strings.ToLower(segments[0]))
+		rest     = segments[1:]
+		elemType = val.Type().Elem()
+	)
+
+	// No further segments: set the raw value directly, converting to the map's value type
+	if len(rest) == 0 {
+		elem := reflect.New(elemType).Elem()
+		setScalar(elem, elemType, raw)
+		val.SetMapIndex(key, elem)
+
+		return true
+	}
+
+	switch elemType.Kind() {
+	case reflect.Map:
+		nested := reflect.New(elemType).Elem()
+		if existing := val.MapIndex(key); existing.IsValid() {
+			nested.Set(existing)
+		} else {
+			nested.Set(reflect.MakeMap(elemType))
+		}
+
+		if !setEnvPath(nested.Addr(), rest, raw) {
+			return false
+		}
+
+		val.SetMapIndex(key, nested)
+
+		return true
+	case reflect.Interface:
+		nested := map[string]interface{}{}
+
+		if existing := val.MapIndex(key); existing.IsValid() {
+			if m, ok := existing.Interface().(map[string]interface{}); ok {
+				nested = m
+			}
+		}
+
+		setInterfaceMapPath(nested, rest, raw)
+		val.SetMapIndex(key, reflect.ValueOf(nested))
+
+		return true
+	default:
+		// The map's value type can't hold any further nesting
+		return false
+	}
+}
+
+// setInterfaceMapPath descends into a `map[string]interface{}`, creating intermediate maps
+// as needed, until the final path segment is reached, where the raw value is set. Values are
+// stored as strings, bools, or float64s (mirroring `encoding/json`'s own decoding rules) so
+// that entries set this way look the same whether they came from the config file or the
+// environment
+func setInterfaceMapPath(m map[string]interface{}, segments []string, raw string) {
+	key := strings.ToLower(segments[0])
+
+	if len(segments) == 1 {
+		m[key] = coerceInterfaceValue(raw)
+
+		return
+	}
+
+	nested, ok := m[key].(map[string]interface{})
+	if !ok {
+		nested = map[string]interface{}{}
+	}
+
+	setInterfaceMapPath(nested, segments[1:], raw)
+	m[key] = nested
+}
+
+// coerceInterfaceValue converts a raw string into a bool, float64, or string, mirroring the
+// types `encoding/json` itself would decode an untyped value into
+func coerceInterfaceValue(raw string) interface{} {
+	if raw == "true" || raw == "false" {
+		return raw == "true"
+	}
+
+	if parsed, err := strconv.ParseFloat(raw, 64); err == nil {
+		return parsed
+	}
+
+	return raw
+}
@@ -0,0 +1,101 @@
+// Tests the env_iteration.go file
+package configurator
+
+import (
+	// Standard lib
+	"os"
+	"reflect"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("env_iteration.go", func() {
+	var (
+		// Mock config to use throughout tests
+		testConfig *TestConfig
+	)
+
+	BeforeEach(func() {
+		// Set up test config
+		testConfig = &TestConfig{}
+
+		// Unset test environment variables
+		os.Unsetenv(EnvPrefix + "ENV_FOO")
+		os.Unsetenv(EnvPrefix + "STORAGE_S3_BUCKET")
+		os.Unsetenv(EnvPrefix + "ENV_MAP_A")
+	})
+
+	Describe("`setFromEnvironment` method", func() {
+		Context("When `EnvIterationMode` is disabled", func() {
+			It("Walks the struct's fields instead of iterating the environment", func() {
+				os.Setenv(EnvPrefix+"ENV_FOO", "foo")
+				defer os.Unsetenv(EnvPrefix + "ENV_FOO")
+
+				setFromEnvironment(testConfig)
+
+				Expect(testConfig.Foo).To(Equal("foo"))
+			})
+		})
+
+		Context("When `EnvIterationMode` is enabled", func() {
+			BeforeEach(func() {
+				EnvIterationMode = true
+			})
+
+			AfterEach(func() {
+				EnvIterationMode = false
+			})
+
+			It("Sets declared fields by iterating the environment", func() {
+				os.Setenv(EnvPrefix+"ENV_FOO", "foo")
+				defer os.Unsetenv(EnvPrefix + "ENV_FOO")
+
+				setFromEnvironment(testConfig)
+
+				Expect(testConfig.Foo).To(Equal("foo"))
+			})
+
+			It("Creates map keys that weren't pre-declared", func() {
+				os.Setenv(EnvPrefix+"STORAGE_S3_BUCKET", "my-bucket")
+				defer os.Unsetenv(EnvPrefix + "STORAGE_S3_BUCKET")
+
+				setFromEnvironment(testConfig)
+
+				s3, ok := testConfig.Storage["s3"].(map[string]interface{})
+				Expect(ok).To(BeTrue())
+				Expect(s3["bucket"]).To(Equal("my-bucket"))
+			})
+
+			It("Creates `map[string]string` keys that weren't pre-declared", func() {
+				os.Setenv(EnvPrefix+"ENV_MAP_A", "1")
+				defer os.Unsetenv(EnvPrefix + "ENV_MAP_A")
+
+				setFromEnvironment(testConfig)
+
+				Expect(testConfig.Map).To(HaveKeyWithValue("a", "1"))
+			})
+
+			It("Sets a declared `Slice` field without panicking", func() {
+				os.Setenv(EnvPrefix+"ENV_SLICE", "a;b;c")
+				defer os.Unsetenv(EnvPrefix + "ENV_SLICE")
+
+				setFromEnvironment(testConfig)
+
+				Expect(testConfig.Slice).To(Equal([]string{"a", "b", "c"}))
+			})
+
+		})
+	})
+
+	Describe("`setEnvPath` method", func() {
+		It("Returns false when the path doesn't match any field", func() {
+			Expect(setEnvPath(reflect.ValueOf(testConfig), []string{"DOESNT", "EXIST"}, "value")).To(BeFalse())
+		})
+
+		It("Returns false, without panicking, when a `Map` field is matched with no remaining path", func() {
+			Expect(setEnvPath(reflect.ValueOf(testConfig), []string{"ENV", "MAP"}, "a:1,b:2")).To(BeFalse())
+		})
+	})
+})
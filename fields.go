@@ -0,0 +1,376 @@
+// Contains additional field "kind" handlers and the `flag.Value` plumbing that backs them,
+// expanding environment variable and flag support beyond bool/int/string
+package configurator
+
+import (
+	// Standard lib
+	"flag"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	// Third-party
+	goutils "github.com/marksost/go-utils"
+)
+
+const (
+	// defaultSeparator is used to split `[]T` and `map[string]T` environment variable and
+	// flag values when a field doesn't specify it's own `separator` tag
+	defaultSeparator = ","
+	// defaultTimeFormat is used to parse `time.Time` environment variable and flag values when
+	// a field doesn't specify it's own `format` tag
+	defaultTimeFormat = time.RFC3339
+	// mapPairSeparator splits a single `k:v` pair within a `map[string]T` value
+	mapPairSeparator = ":"
+)
+
+// fieldValue adapts a reflected struct field, along with a function capable of parsing
+// and setting a raw string onto it, to the `flag.Value` interface. This allows any of the
+// additional "kinds" handled in this file to be registered and overridden as a command-line flag
+type fieldValue struct {
+	field reflect.Value
+	parse func(field reflect.Value, raw string) error
+}
+
+// newFieldValue builds a `fieldValue` for the given field and parse function
+func newFieldValue(field reflect.Value, parse func(reflect.Value, string) error) *fieldValue {
+	return &fieldValue{field: field, parse: parse}
+}
+
+// String satisfies the `flag.Value` interface, returning the field's current value
+func (f *fieldValue) String() string {
+	if !f.field.IsValid() {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", f.field.Interface())
+}
+
+// Set satisfies the `flag.Value` interface, parsing and setting a raw flag value onto the field
+func (f *fieldValue) Set(raw string) error {
+	return f.parse(f.field, raw)
+}
+
+// parseInt parses a raw value as a signed integer, sized to the field's own bit width
+func parseInt(field reflect.Value, raw string) error {
+	parsed, err := strconv.ParseInt(raw, 10, field.Type().Bits())
+	if err != nil {
+		return err
+	}
+
+	field.SetInt(parsed)
+
+	return nil
+}
+
+// parseUint parses a raw value as an unsigned integer, sized to the field's own bit width
+func parseUint(field reflect.Value, raw string) error {
+	parsed, err := strconv.ParseUint(raw, 10, field.Type().Bits())
+	if err != nil {
+		return err
+	}
+
+	field.SetUint(parsed)
+
+	return nil
+}
+
+// parseFloat parses a raw value as a floating-point number, sized to the field's own bit width
+func parseFloat(field reflect.Value, raw string) error {
+	parsed, err := strconv.ParseFloat(raw, field.Type().Bits())
+	if err != nil {
+		return err
+	}
+
+	field.SetFloat(parsed)
+
+	return nil
+}
+
+// handleUintEnvironmentVariable handles fields with an unsigned integer "kind", of any bit size
+// Sets a field's value as well as a flag (when allowed)
+func handleUintEnvironmentVariable(v reflect.Value, i int, flagName string, env string) error {
+	// Store field
+	field := v.Field(i)
+	var err error
+
+	// Handle non-empty environment variable
+	if env != "" {
+		err = parseUint(field, env)
+	}
+
+	// If allowed, set a flag
+	// NOTE: Checks PkgPath for empty value, meaning the field is exported
+	// and thus reflect's Interface method can return it's value
+	// See https://golang.org/pkg/reflect/#StructField for more information
+	if flag.Lookup(flagName) == nil && v.Type().Field(i).PkgPath == "" {
+		flag.Var(newFieldValue(field, parseUint), flagName, "")
+	}
+
+	return err
+}
+
+// handleFloatEnvironmentVariable handles fields with a "kind" of float32 or float64
+// Sets a field's value as well as a flag (when allowed)
+func handleFloatEnvironmentVariable(v reflect.Value, i int, flagName string, env string) error {
+	// Store field
+	field := v.Field(i)
+	var err error
+
+	// Handle non-empty environment variable
+	if env != "" {
+		err = parseFloat(field, env)
+	}
+
+	// If allowed, set a flag
+	// NOTE: Checks PkgPath for empty value, meaning the field is exported
+	// and thus reflect's Interface method can return it's value
+	// See https://golang.org/pkg/reflect/#StructField for more information
+	if flag.Lookup(flagName) == nil && v.Type().Field(i).PkgPath == "" {
+		flag.Var(newFieldValue(field, parseFloat), flagName, "")
+	}
+
+	return err
+}
+
+// handleDurationEnvironmentVariable handles fields of type `time.Duration`
+// Sets a field's value as well as a flag (when allowed)
+func handleDurationEnvironmentVariable(v reflect.Value, i int, flagName string, env string) error {
+	// Store field
+	field := v.Field(i)
+	var err error
+
+	// Handle non-empty environment variable
+	if env != "" {
+		var parsed time.Duration
+		if parsed, err = time.ParseDuration(env); err == nil {
+			field.SetInt(int64(parsed))
+		}
+	}
+
+	// If allowed, set a flag
+	// NOTE: Checks PkgPath for empty value, meaning the field is exported
+	// and thus reflect's Interface method can return it's value
+	// See https://golang.org/pkg/reflect/#StructField for more information
+	if flag.Lookup(flagName) == nil && v.Type().Field(i).PkgPath == "" {
+		ptr := field.Addr().Interface().(*time.Duration)
+		flag.DurationVar(ptr, flagName, *ptr, "")
+	}
+
+	return err
+}
+
+// handleTimeEnvironmentVariable handles fields of type `time.Time`, parsed using the layout
+// found under a field's `format` tag, defaulting to `time.RFC3339`
+// Sets a field's value as well as a flag (when allowed)
+func handleTimeEnvironmentVariable(v reflect.Value, i int, flagName string, env string) error {
+	var (
+		field  = v.Field(i)
+		format = v.Type().Field(i).Tag.Get("format")
+		err    error
+	)
+
+	if format == "" {
+		format = defaultTimeFormat
+	}
+
+	// Handle non-empty environment variable
+	if env != "" {
+		var parsed time.Time
+		if parsed, err = time.Parse(format, env); err == nil {
+			field.Set(reflect.ValueOf(parsed))
+		}
+	}
+
+	// If allowed, set a flag
+	// NOTE: Checks PkgPath for empty value, meaning the field is exported
+	// and thus reflect's Interface method can return it's value
+	// See https://golang.org/pkg/reflect/#StructField for more information
+	if flag.Lookup(flagName) == nil && v.Type().Field(i).PkgPath == "" {
+		flag.Var(newFieldValue(field, func(field reflect.Value, raw string) error {
+			parsed, err := time.Parse(format, raw)
+			if err != nil {
+				return err
+			}
+
+			field.Set(reflect.ValueOf(parsed))
+
+			return nil
+		}), flagName, "")
+	}
+
+	return err
+}
+
+// handleURLEnvironmentVariable handles fields of type `url.URL`
+// Sets a field's value as well as a flag (when allowed)
+func handleURLEnvironmentVariable(v reflect.Value, i int, flagName string, env string) error {
+	// Store field
+	field := v.Field(i)
+	var err error
+
+	// Handle non-empty environment variable
+	if env != "" {
+		var parsed *url.URL
+		if parsed, err = url.Parse(env); err == nil {
+			field.Set(reflect.ValueOf(*parsed))
+		}
+	}
+
+	// If allowed, set a flag
+	// NOTE: Checks PkgPath for empty value, meaning the field is exported
+	// and thus reflect's Interface method can return it's value
+	// See https://golang.org/pkg/reflect/#StructField for more information
+	if flag.Lookup(flagName) == nil && v.Type().Field(i).PkgPath == "" {
+		flag.Var(newFieldValue(field, func(field reflect.Value, raw string) error {
+			parsed, err := url.Parse(raw)
+			if err != nil {
+				return err
+			}
+
+			field.Set(reflect.ValueOf(*parsed))
+
+			return nil
+		}), flagName, "")
+	}
+
+	return err
+}
+
+// handleSliceEnvironmentVariable handles fields with a "kind" of slice, splitting the raw
+// value on the field's `separator` tag (defaulting to a comma) and parsing each element
+// according to the slice's element type
+// Sets a field's value as well as a flag (when allowed)
+func handleSliceEnvironmentVariable(v reflect.Value, i int, flagName string, env string) error {
+	var (
+		field     = v.Field(i)
+		separator = v.Type().Field(i).Tag.Get("separator")
+		err       error
+	)
+
+	if separator == "" {
+		separator = defaultSeparator
+	}
+
+	// Handle non-empty environment variable
+	if env != "" {
+		err = setSlice(field, env, separator)
+	}
+
+	// If allowed, set a flag
+	// NOTE: Checks PkgPath for empty value, meaning the field is exported
+	// and thus reflect's Interface method can return it's value
+	// See https://golang.org/pkg/reflect/#StructField for more information
+	if flag.Lookup(flagName) == nil && v.Type().Field(i).PkgPath == "" {
+		flag.Var(newFieldValue(field, func(field reflect.Value, raw string) error {
+			return setSlice(field, raw, separator)
+		}), flagName, "")
+	}
+
+	return err
+}
+
+// setSlice splits a raw value on the given separator and assigns the resulting elements,
+// converted to the slice's element type, onto the field
+func setSlice(field reflect.Value, raw string, separator string) error {
+	var (
+		elemType = field.Type().Elem()
+		parts    = strings.Split(raw, separator)
+		slice    = reflect.MakeSlice(field.Type(), len(parts), len(parts))
+	)
+
+	for i, part := range parts {
+		setScalar(slice.Index(i), elemType, strings.TrimSpace(part))
+	}
+
+	field.Set(slice)
+
+	return nil
+}
+
+// handleMapEnvironmentVariable handles fields with a "kind" of `map[string]T`, parsing the raw
+// value as a list of `k1:v1,k2:v2` pairs (split with the field's `separator` tag, defaulting to
+// a comma), allocating the map if needed, and converting each value to the map's value type
+// Sets a field's value as well as a flag (when allowed)
+func handleMapEnvironmentVariable(v reflect.Value, i int, flagName string, env string) error {
+	var (
+		field     = v.Field(i)
+		separator = v.Type().Field(i).Tag.Get("separator")
+	)
+
+	if separator == "" {
+		separator = defaultSeparator
+	}
+
+	// Handle non-empty environment variable
+	if env != "" {
+		setMap(field, env, separator)
+	}
+
+	// If allowed, set a flag
+	// NOTE: Checks PkgPath for empty value, meaning the field is exported
+	// and thus reflect's Interface method can return it's value
+	// See https://golang.org/pkg/reflect/#StructField for more information
+	if flag.Lookup(flagName) == nil && v.Type().Field(i).PkgPath == "" {
+		flag.Var(newFieldValue(field, func(field reflect.Value, raw string) error {
+			setMap(field, raw, separator)
+
+			return nil
+		}), flagName, "")
+	}
+
+	return nil
+}
+
+// setMap splits a raw `k1:v1,k2:v2` value into pairs, converts each value to the map's
+// value type, and assigns them onto the field, allocating the map if it's nil
+func setMap(field reflect.Value, raw string, separator string) {
+	var (
+		elemType = field.Type().Elem()
+		pairs    = strings.Split(raw, separator)
+	)
+
+	if field.IsNil() {
+		field.Set(reflect.MakeMap(field.Type()))
+	}
+
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, mapPairSeparator, 2)
+		if len(kv) != 2 {
+			continue
+		}
+
+		value := reflect.New(elemType).Elem()
+		setScalar(value, elemType, strings.TrimSpace(kv[1]))
+
+		field.SetMapIndex(reflect.ValueOf(strings.TrimSpace(kv[0])), value)
+	}
+}
+
+// setScalar converts a raw string to the given type and sets it onto a (addressable) value,
+// used as the common element conversion for both slices and maps
+func setScalar(dest reflect.Value, kind reflect.Type, raw string) {
+	switch kind.Kind() {
+	case reflect.Bool:
+		dest.SetBool(goutils.String2Bool(raw))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if parsed, err := strconv.ParseInt(raw, 10, kind.Bits()); err == nil {
+			dest.SetInt(parsed)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if parsed, err := strconv.ParseUint(raw, 10, kind.Bits()); err == nil {
+			dest.SetUint(parsed)
+		}
+	case reflect.Float32, reflect.Float64:
+		if parsed, err := strconv.ParseFloat(raw, kind.Bits()); err == nil {
+			dest.SetFloat(parsed)
+		}
+	case reflect.Interface:
+		dest.Set(reflect.ValueOf(coerceInterfaceValue(raw)))
+	default:
+		dest.SetString(raw)
+	}
+}
@@ -0,0 +1,120 @@
+// Contains a pluggable secret resolver, allowing env and config file values to reference
+// something other than their final value (e.g. a path to a file mounted by Docker/Kubernetes
+// secrets) via a scheme prefix, such as `file://`
+package configurator
+
+import (
+	// Standard lib
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver dereferences a raw value carrying the scheme it's registered under (e.g. a
+// "file://" path) into the value that should actually be assigned to a field
+type Resolver interface {
+	Resolve(ref string) (string, error)
+}
+
+// schemePattern matches a leading "scheme://" prefix on a raw value
+var schemePattern = regexp.MustCompile(`^([a-zA-Z][a-zA-Z0-9+.-]*)://`)
+
+var (
+	// resolversMu guards resolvers, since `RegisterResolver` may be called concurrently
+	// with resolution happening as part of `InitializeConfig`
+	resolversMu sync.RWMutex
+	// resolvers maps a scheme (the part of a raw value before "://") to the `Resolver`
+	// responsible for dereferencing it
+	resolvers = map[string]Resolver{
+		"file": fileResolver{},
+	}
+)
+
+// RegisterResolver associates a `Resolver` with a scheme, overriding any `Resolver` already
+// registered for it. The built-in `file` scheme can be overridden this way as well
+// NOTE: This can be called from outside this package before calling `InitializeConfig`
+func RegisterResolver(scheme string, r Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+
+	resolvers[scheme] = r
+}
+
+// resolveValue checks a raw value for a registered scheme prefix and, if found, dereferences
+// it through the matching `Resolver`. Values with no matching scheme are returned unchanged
+func resolveValue(raw string) (string, error) {
+	match := schemePattern.FindStringSubmatch(raw)
+	if match == nil {
+		return raw, nil
+	}
+
+	resolversMu.RLock()
+	r, ok := resolvers[match[1]]
+	resolversMu.RUnlock()
+
+	if !ok {
+		return raw, nil
+	}
+
+	return r.Resolve(raw)
+}
+
+// fileResolver is the built-in `file` `Resolver`. It reads a secret mounted on disk, as is
+// commonly done with Docker/Kubernetes secrets, so they don't need to be baked into env vars
+type fileResolver struct{}
+
+// Resolve reads the file referenced by a `file://` value and returns it's trimmed contents
+func (fileResolver) Resolve(ref string) (string, error) {
+	path := strings.TrimPrefix(ref, "file://")
+
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %q: %s", ref, err)
+	}
+
+	return strings.TrimSpace(string(contents)), nil
+}
+
+// resolveConfigValues walks a decoded config struct's string fields after a config file has
+// been unmarshalled, dereferencing any that carry a registered scheme prefix
+// NOTE: Best-effort; a value that fails to resolve is left as-is
+func resolveConfigValues(c interface{}) {
+	handleConfigValueResolution(reflect.ValueOf(c))
+}
+
+// handleConfigValueResolution recurses through a reflected value's fields, resolving string
+// fields in place and descending into nested structs (other than the leaf types handled
+// elsewhere in this package)
+// NOTE: Abstracted from `resolveConfigValues` to allow for struct recursion
+func handleConfigValueResolution(v reflect.Value) {
+	val := reflect.Indirect(v)
+	if val.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Field(i)
+		if val.Type().Field(i).PkgPath != "" {
+			continue
+		}
+
+		switch field.Kind() {
+		case reflect.String:
+			if resolved, err := resolveValue(field.String()); err == nil {
+				field.SetString(resolved)
+			}
+		case reflect.Struct:
+			switch field.Interface().(type) {
+			case time.Time, url.URL:
+				// Leaf types handled elsewhere, not walked here
+			default:
+				handleConfigValueResolution(field.Addr())
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+// Tests the secrets.go file
+package configurator
+
+import (
+	// Standard lib
+	"fmt"
+	"os"
+	"path"
+	"reflect"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// upperResolver is a test-only `Resolver` used to verify `RegisterResolver`'s plumbing
+type upperResolver struct{}
+
+func (upperResolver) Resolve(ref string) (string, error) {
+	return fmt.Sprintf("resolved:%s", ref), nil
+}
+
+var _ = Describe("secrets.go", func() {
+	Describe("`resolveValue` method", func() {
+		Context("When a value has no scheme prefix", func() {
+			It("Returns the value unchanged", func() {
+				resolved, err := resolveValue("plain-value")
+
+				Expect(err).To(BeNil())
+				Expect(resolved).To(Equal("plain-value"))
+			})
+		})
+
+		Context("When a value has an unregistered scheme prefix", func() {
+			It("Returns the value unchanged", func() {
+				resolved, err := resolveValue("vault://secret/data/app#password")
+
+				Expect(err).To(BeNil())
+				Expect(resolved).To(Equal("vault://secret/data/app#password"))
+			})
+		})
+
+		Context("When a value has the built-in `file://` scheme prefix", func() {
+			Context("And the referenced file exists", func() {
+				It("Returns the file's trimmed contents", func() {
+					resolved, err := resolveValue("file://" + path.Join("test/data/secret.txt"))
+
+					Expect(err).To(BeNil())
+					Expect(resolved).To(Equal("s3kr3t"))
+				})
+			})
+
+			Context("And the referenced file doesn't exist", func() {
+				It("Returns an error", func() {
+					_, err := resolveValue("file://" + path.Join("test/data/doesnt-exist.txt"))
+
+					Expect(err).To(Not(BeNil()))
+				})
+			})
+		})
+
+		Context("When a value has a scheme registered via `RegisterResolver`", func() {
+			BeforeEach(func() {
+				RegisterResolver("upper", upperResolver{})
+			})
+
+			AfterEach(func() {
+				resolversMu.Lock()
+				delete(resolvers, "upper")
+				resolversMu.Unlock()
+			})
+
+			It("Dereferences the value through the registered `Resolver`", func() {
+				resolved, err := resolveValue("upper://foo")
+
+				Expect(err).To(BeNil())
+				Expect(resolved).To(Equal("resolved:upper://foo"))
+			})
+		})
+	})
+
+	Describe("`handleEnvironmentVariables` method", func() {
+		var (
+			// Mock config to use throughout tests
+			testConfig *TestConfig
+		)
+
+		BeforeEach(func() {
+			testConfig = &TestConfig{}
+
+			os.Setenv(EnvPrefix+"ENV_SECRET", "file://"+path.Join("test/data/secret.txt"))
+		})
+
+		AfterEach(func() {
+			os.Unsetenv(EnvPrefix + "ENV_SECRET")
+		})
+
+		It("Resolves a `file://` environment variable value before setting it", func() {
+			handleEnvironmentVariables(reflect.ValueOf(testConfig), "")
+
+			Expect(testConfig.Secret).To(Equal("s3kr3t"))
+		})
+	})
+
+	Describe("`resolveConfigValues` method", func() {
+		It("Resolves `file://` values set by a config file in place", func() {
+			testConfig := &TestConfig{Secret: "file://" + path.Join("test/data/secret.txt")}
+
+			resolveConfigValues(testConfig)
+
+			Expect(testConfig.Secret).To(Equal("s3kr3t"))
+		})
+	})
+})
@@ -0,0 +1,244 @@
+// Contains structured error reporting for `InitializeConfig`, along with support for the
+// `required`, `validate`, and `expand` struct tags
+package configurator
+
+import (
+	// Standard lib
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrorHandling mirrors `flag.ErrorHandling`, controlling what `InitializeConfig` does when
+// it accumulates one or more `FieldError`s
+type ErrorHandling int
+
+const (
+	// ContinueOnError returns the aggregated error to the caller
+	ContinueOnError ErrorHandling = iota
+	// ExitOnError prints the aggregated error to stderr and calls `os.Exit(2)`
+	ExitOnError
+	// PanicOnError panics with the aggregated error
+	PanicOnError
+)
+
+// ErrorHandlingMode governs how `InitializeConfig` reports the errors it accumulates
+// NOTE: This can be changed from outside this package before calling `InitializeConfig`
+var ErrorHandlingMode = ContinueOnError
+
+// FieldError describes a single field-level failure encountered while initializing a config,
+// e.g. a value that failed to parse, or a `required` field that was never set
+type FieldError struct {
+	Field string // Dotted path to the field, e.g. "Test.Foo"
+	Tag   string // Which tag drove the failure: "default", "env", "required", or "validate"
+	Value string // The raw value that was being processed, if any
+	Err   error  // The underlying error
+}
+
+// Error satisfies the `error` interface
+func (e FieldError) Error() string {
+	if e.Value == "" {
+		return fmt.Sprintf("%s (%s): %s", e.Field, e.Tag, e.Err)
+	}
+
+	return fmt.Sprintf("%s (%s): %q: %s", e.Field, e.Tag, e.Value, e.Err)
+}
+
+// ConfigError aggregates every `FieldError` encountered while initializing a config
+type ConfigError struct {
+	Errors []FieldError
+}
+
+// Error satisfies the `error` interface, joining every field-level error onto it's own line
+func (e *ConfigError) Error() string {
+	lines := make([]string, len(e.Errors))
+
+	for i, err := range e.Errors {
+		lines[i] = err.Error()
+	}
+
+	return fmt.Sprintf("configurator: %d field error(s):\n%s", len(e.Errors), strings.Join(lines, "\n"))
+}
+
+// fieldPath joins a parent path and a field name into a dotted path, used to identify
+// fields in nested structs within a `FieldError`
+func fieldPath(parent string, name string) string {
+	if parent == "" {
+		return name
+	}
+
+	return parent + "." + name
+}
+
+// handleConfigErrors turns a slice of accumulated `FieldError`s into the return value (or side
+// effect) dictated by `ErrorHandlingMode`, mirroring how `flag.ErrorHandling` is applied
+func handleConfigErrors(errs []FieldError) error {
+	if len(errs) == 0 {
+		return nil
+	}
+
+	err := &ConfigError{Errors: errs}
+
+	switch ErrorHandlingMode {
+	case ExitOnError:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	case PanicOnError:
+		panic(err)
+	}
+
+	return err
+}
+
+// validateFields walks a reflected value's fields, checking `required` and `validate` tags
+// now that defaults, the config file, and the environment have all had a chance to set a value
+func validateFields(v reflect.Value, path string) []FieldError {
+	val := reflect.Indirect(v)
+
+	var errs []FieldError
+
+	for i := 0; i < val.NumField(); i++ {
+		var (
+			field = val.Field(i)
+			tag   = val.Type().Field(i)
+			name  = fieldPath(path, tag.Name)
+		)
+
+		// Skip unexported fields: `field.Interface()` below would otherwise panic
+		// See https://golang.org/pkg/reflect/#StructField for more information
+		if tag.PkgPath != "" {
+			continue
+		}
+
+		// Recurse into nested structs, unless this one is itself treated as a leaf value
+		// elsewhere (e.g. `time.Time`, `url.URL`, both of which implement `fmt.Stringer`).
+		// Checked against `field.Addr()` rather than `field` itself: `url.URL`'s `String()`
+		// has a pointer receiver, so the value alone never satisfies `fmt.Stringer`
+		_, isLeaf := field.Addr().Interface().(fmt.Stringer)
+		if field.Kind() == reflect.Struct && !isLeaf {
+			errs = append(errs, validateFields(field.Addr(), name)...)
+			continue
+		}
+
+		if tag.Tag.Get("required") == "true" && field.IsZero() {
+			errs = append(errs, FieldError{Field: name, Tag: "required", Err: fmt.Errorf("field is required but was never set")})
+			continue
+		}
+
+		if rule := tag.Tag.Get("validate"); rule != "" && !field.IsZero() {
+			if err := validateField(field, rule); err != nil {
+				errs = append(errs, FieldError{Field: name, Tag: "validate", Value: fmt.Sprintf("%v", field.Interface()), Err: err})
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateField checks a field's value against a `;`-separated list of `rule=value` pairs,
+// e.g. `validate:"min=1;max=100"` or `validate:"oneof=a|b|c"` or `validate:"regexp=^[a-z]+$"`
+func validateField(field reflect.Value, tag string) error {
+	for _, rule := range strings.Split(tag, ";") {
+		parts := strings.SplitN(rule, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		name, arg := parts[0], parts[1]
+
+		var err error
+
+		switch name {
+		case "min":
+			err = validateMin(field, arg)
+		case "max":
+			err = validateMax(field, arg)
+		case "oneof":
+			err = validateOneof(field, arg)
+		case "regexp":
+			err = validateRegexp(field, arg)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateMin checks that a numeric field is at least `arg`
+func validateMin(field reflect.Value, arg string) error {
+	min, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return err
+	}
+
+	if numericValue(field) < min {
+		return fmt.Errorf("must be at least %s", arg)
+	}
+
+	return nil
+}
+
+// validateMax checks that a numeric field is at most `arg`
+func validateMax(field reflect.Value, arg string) error {
+	max, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return err
+	}
+
+	if numericValue(field) > max {
+		return fmt.Errorf("must be at most %s", arg)
+	}
+
+	return nil
+}
+
+// validateOneof checks that a field's value, formatted as a string (through it's `String`
+// method for a leaf struct such as `url.URL`), is one of a `|`-separated list of options
+func validateOneof(field reflect.Value, arg string) error {
+	value := fmt.Sprintf("%v", field.Interface())
+
+	// `url.URL`'s `String` has a pointer receiver, so the struct value alone doesn't format through it
+	if s, ok := field.Addr().Interface().(fmt.Stringer); ok && field.Kind() == reflect.Struct {
+		value = s.String()
+	}
+
+	for _, option := range strings.Split(arg, "|") {
+		if value == option {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("must be one of %q", arg)
+}
+
+// validateRegexp checks that a string field's value matches the given pattern
+func validateRegexp(field reflect.Value, pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return err
+	}
+
+	if !re.MatchString(fmt.Sprintf("%v", field.Interface())) {
+		return fmt.Errorf("must match %q", pattern)
+	}
+
+	return nil
+}
+
+// numericValue returns a field's value as a float64, regardless of it's specific numeric kind
+func numericValue(field reflect.Value) float64 {
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint())
+	default:
+		return field.Float()
+	}
+}
@@ -0,0 +1,155 @@
+// Tests the validation.go file
+package configurator
+
+import (
+	// Standard lib
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// findFieldError returns the first `FieldError` matching the given field name, or nil
+func findFieldError(errs []FieldError, field string) *FieldError {
+	for _, err := range errs {
+		if err.Field == field {
+			return &err
+		}
+	}
+
+	return nil
+}
+
+var _ = Describe("validation.go", func() {
+	var (
+		// Mock config to use throughout tests
+		testConfig *TestConfig
+	)
+
+	BeforeEach(func() {
+		// Set up test config
+		testConfig = &TestConfig{}
+
+		// Satisfy the `required` field so other tests can focus on the behavior they target
+		testConfig.Required = "present"
+	})
+
+	Describe("`validateFields` method", func() {
+		Context("When a `required` field was never set", func() {
+			BeforeEach(func() {
+				testConfig.Required = ""
+			})
+
+			It("Returns a `FieldError` for the field", func() {
+				err := findFieldError(validateFields(reflect.ValueOf(testConfig), ""), "Required")
+
+				Expect(err).To(Not(BeNil()))
+				Expect(err.Tag).To(Equal("required"))
+			})
+		})
+
+		Context("When a `validate:\"min=...;max=...\"` field is out of range", func() {
+			BeforeEach(func() {
+				testConfig.Validated = 100
+			})
+
+			It("Returns a `FieldError` for the field", func() {
+				err := findFieldError(validateFields(reflect.ValueOf(testConfig), ""), "Validated")
+
+				Expect(err).To(Not(BeNil()))
+				Expect(err.Tag).To(Equal("validate"))
+			})
+		})
+
+		Context("When a `url.URL` field (whose `String` method has a pointer receiver) fails it's `validate` tag", func() {
+			BeforeEach(func() {
+				testConfig.URL = url.URL{Scheme: "http", Host: "evil.example"}
+			})
+
+			It("Treats the field as a leaf instead of recursing into it's internal fields, returning a `FieldError` for it", func() {
+				err := findFieldError(validateFields(reflect.ValueOf(testConfig), ""), "URL")
+
+				Expect(err).To(Not(BeNil()))
+				Expect(err.Tag).To(Equal("validate"))
+			})
+		})
+
+		Context("When every field satisfies it's tags", func() {
+			BeforeEach(func() {
+				testConfig.Validated = 5
+			})
+
+			It("Returns no errors", func() {
+				Expect(validateFields(reflect.ValueOf(testConfig), "")).To(BeEmpty())
+			})
+		})
+	})
+
+	Describe("`fieldPath` method", func() {
+		It("Joins a parent path and field name with a dot", func() {
+			Expect(fieldPath("", "Foo")).To(Equal("Foo"))
+			Expect(fieldPath("Test", "Foo")).To(Equal("Test.Foo"))
+		})
+	})
+
+	Describe("`handleConfigErrors` method", func() {
+		AfterEach(func() {
+			// Restore default handling mode
+			ErrorHandlingMode = ContinueOnError
+		})
+
+		Context("When no errors occurred", func() {
+			It("Returns nil", func() {
+				Expect(handleConfigErrors(nil)).To(BeNil())
+			})
+		})
+
+		Context("With `ErrorHandlingMode` set to `ContinueOnError`", func() {
+			It("Returns a `*ConfigError` aggregating every field error", func() {
+				err := handleConfigErrors([]FieldError{
+					{Field: "Foo", Tag: "required", Err: fmt.Errorf("field is required but was never set")},
+				})
+
+				Expect(err).To(BeAssignableToTypeOf(&ConfigError{}))
+				Expect(err.Error()).To(ContainSubstring("Foo (required)"))
+			})
+		})
+	})
+
+	Describe("`expand` tag handling", func() {
+		BeforeEach(func() {
+			os.Setenv("CONFIGURATOR_TEST_EXPAND", "expanded-value")
+		})
+
+		AfterEach(func() {
+			os.Unsetenv("CONFIGURATOR_TEST_EXPAND")
+		})
+
+		It("Expands `${VAR}` references found in default values", func() {
+			handleDefaults(reflect.ValueOf(testConfig), "")
+
+			Expect(testConfig.Expanded).To(Equal("expanded-value"))
+		})
+
+		It("Expands `${VAR}` references found in environment variable values", func() {
+			os.Setenv(EnvPrefix+"ENV_EXPANDED", "${CONFIGURATOR_TEST_EXPAND}")
+			defer os.Unsetenv(EnvPrefix + "ENV_EXPANDED")
+
+			handleEnvironmentVariables(reflect.ValueOf(testConfig), "")
+
+			Expect(testConfig.Expanded).To(Equal("expanded-value"))
+		})
+	})
+
+	Describe("`ErrorHandling` constants", func() {
+		It("Defines `ContinueOnError`, `ExitOnError`, and `PanicOnError`, in that order", func() {
+			Expect(ContinueOnError).To(Equal(ErrorHandling(0)))
+			Expect(ExitOnError).To(Equal(ErrorHandling(1)))
+			Expect(PanicOnError).To(Equal(ErrorHandling(2)))
+		})
+	})
+})
@@ -0,0 +1,222 @@
+// Contains a hot-reload subsystem, turning the package from a one-shot loader into something
+// usable by long-running services: `Watch` monitors the configuration file found under
+// `ConfigLocation` and re-runs the initialization pipeline into a fresh copy of the config
+// whenever it changes, notifying any `OnChange` callbacks along the way
+package configurator
+
+import (
+	// Standard lib
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	// Third-party
+	"github.com/fsnotify/fsnotify"
+)
+
+// watchDebounce coalesces bursts of filesystem events (e.g. an editor's save sequence writing
+// the file more than once) into a single reload, rather than reloading once per event
+const watchDebounce = 200 * time.Millisecond
+
+// WatchOption configures a `Watcher` returned by `Watch`
+type WatchOption func(*Watcher)
+
+// OnChange registers a callback invoked, in the order it was added, with the previous and
+// newly-loaded configuration whenever `Watch` successfully reloads the watched file
+func OnChange(fn func(old interface{}, new interface{})) WatchOption {
+	return func(w *Watcher) {
+		w.callbacks = append(w.callbacks, fn)
+	}
+}
+
+// OnError registers a callback invoked, in the order it was added, whenever a reload's
+// `InitializeConfig` call reports a `FieldError`. This fires both when a reload is dropped
+// entirely (a field failed to parse) and when it's applied despite failing a `required` or
+// `validate` check (see `isValidationOnlyError`), which is the only way for a caller to learn
+// that `Get` is now returning a value that doesn't satisfy its own constraints
+func OnError(fn func(err error)) WatchOption {
+	return func(w *Watcher) {
+		w.errorCallbacks = append(w.errorCallbacks, fn)
+	}
+}
+
+// Watcher hot-reloads a configuration struct whenever it's backing config file changes on
+// disk. The current value is guarded by an `RWMutex` so it can be read safely from other
+// goroutines via `Get` while a reload is in progress
+type Watcher struct {
+	mu             sync.RWMutex
+	current        interface{}
+	fsWatcher      *fsnotify.Watcher
+	callbacks      []func(old interface{}, new interface{})
+	errorCallbacks []func(err error)
+	done           chan struct{}
+}
+
+// Watch begins monitoring the configuration file found under `ConfigLocation` (the same one
+// `InitializeConfig` reads from) for changes via `fsnotify`. On every change, the defaults ->
+// config file -> environment pipeline is re-run into a fresh copy of `c`'s underlying type,
+// which then atomically replaces the value returned by `Get`.
+// NOTE: `c` should already have been passed through `InitializeConfig`; it's used here only to
+// determine it's underlying type and as the initial value behind `Get`
+// NOTE: Flags are bound to the fields of the struct passed to the first `InitializeConfig` call
+// and aren't rebound on reload: a reload parses them onto those original fields again, not onto
+// the fresh copy, so a value returned by `Get` after a reload carries no command-line flag values
+// NOTE: Callers are expected to call `Stop` once the watcher is no longer needed
+func Watch(c interface{}, opts ...WatchOption) (*Watcher, error) {
+	file := os.Getenv(ConfigLocation)
+	if file == "" {
+		return nil, fmt.Errorf("no config file location found under environment variable %s", ConfigLocation)
+	}
+
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	// Watch the containing directory rather than the file itself: an editor's rename-then-write
+	// save pattern removes the original inode, which would otherwise drop the watch entirely
+	if err = fsWatcher.Add(filepath.Dir(file)); err != nil {
+		fsWatcher.Close()
+		return nil, err
+	}
+
+	w := &Watcher{
+		current:   c,
+		fsWatcher: fsWatcher,
+		done:      make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	go w.run(file)
+
+	return w, nil
+}
+
+// Get returns the most recently loaded configuration value, safe for concurrent use
+// alongside reloads triggered by the watched file changing
+func (w *Watcher) Get() interface{} {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	return w.current
+}
+
+// Stop stops watching the configuration file and releases the underlying `fsnotify.Watcher`
+func (w *Watcher) Stop() error {
+	close(w.done)
+
+	return w.fsWatcher.Close()
+}
+
+// run is the `Watcher`'s event loop. It debounces bursts of filesystem events into a single
+// reload and re-adds the watch when an editor replaces the file via rename-then-write
+func (w *Watcher) run(file string) {
+	var timer *time.Timer
+
+	for {
+		select {
+		case <-w.done:
+			if timer != nil {
+				timer.Stop()
+			}
+
+			return
+		case event, ok := <-w.fsWatcher.Events:
+			if !ok {
+				return
+			}
+
+			if filepath.Clean(event.Name) != filepath.Clean(file) {
+				continue
+			}
+
+			// An editor's rename-then-write save pattern removes the original inode: re-add
+			// the watch so events on the file's eventual replacement keep arriving
+			if event.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+				// TO-DO: Logging?
+				_ = w.fsWatcher.Add(filepath.Dir(file))
+			}
+
+			if timer != nil {
+				timer.Stop()
+			}
+
+			timer = time.AfterFunc(watchDebounce, func() {
+				w.reload(file)
+			})
+		case _, ok := <-w.fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			// TO-DO: Logging?
+		}
+	}
+}
+
+// reload re-runs the initialization pipeline into a fresh copy of the watched config's
+// underlying type, swaps it in behind `mu`, and notifies every registered `OnChange` callback
+func (w *Watcher) reload(file string) {
+	w.mu.RLock()
+	old := w.current
+	w.mu.RUnlock()
+
+	fresh := reflect.New(reflect.TypeOf(old).Elem()).Interface()
+
+	if err := InitializeConfig(fresh); err != nil {
+		w.notifyError(err)
+
+		if !isValidationOnlyError(err) {
+			return
+		}
+
+		// A `required`/`validate` failure doesn't mean the reload itself went wrong (the
+		// watched file may simply omit a field it never carried to begin with); swap the
+		// fresh value in regardless so a single unmet constraint doesn't wedge the watcher
+		// forever. `OnError` above is the only way a caller learns `Get` now returns a value
+		// that fails its own constraints
+	}
+
+	w.mu.Lock()
+	w.current = fresh
+	w.mu.Unlock()
+
+	for _, cb := range w.callbacks {
+		cb(old, fresh)
+	}
+}
+
+// notifyError invokes every registered `OnError` callback, in the order added, with a reload's
+// `InitializeConfig` error
+func (w *Watcher) notifyError(err error) {
+	for _, cb := range w.errorCallbacks {
+		cb(err)
+	}
+}
+
+// isValidationOnlyError reports whether every `FieldError` aggregated by `InitializeConfig`
+// came from the `required` or `validate` tags, as opposed to a field whose default or
+// environment value failed to parse or resolve. Those two cases are distinguished so that a
+// reload whose new value merely fails a constraint can still be swapped in, while one with a
+// default or environment value that failed to parse is not. A config file that can't be read
+// or decoded produces no `FieldError` at all, and doesn't by itself stop a reload being swapped in
+func isValidationOnlyError(err error) bool {
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) {
+		return false
+	}
+
+	for _, fieldErr := range configErr.Errors {
+		if fieldErr.Tag != "required" && fieldErr.Tag != "validate" {
+			return false
+		}
+	}
+
+	return true
+}
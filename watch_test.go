@@ -0,0 +1,156 @@
+// Tests the watch.go file
+package configurator
+
+import (
+	// Standard lib
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	// Third-party
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("watch.go", func() {
+	var (
+		// Temp directory holding the watched config file
+		dir string
+		// Path to the watched config file
+		file string
+	)
+
+	BeforeEach(func() {
+		// Set up a temp config file to watch
+		dir, _ = ioutil.TempDir("", "configurator-watch-test")
+		file = filepath.Join(dir, "config.json")
+
+		ioutil.WriteFile(file, []byte(`{"foo":"abcd"}`), 0644)
+
+		os.Setenv(ConfigLocation, file)
+	})
+
+	AfterEach(func() {
+		os.Unsetenv(ConfigLocation)
+		os.RemoveAll(dir)
+	})
+
+	Describe("`Watch` method", func() {
+		Context("When no config location environment variable is set", func() {
+			BeforeEach(func() {
+				os.Unsetenv(ConfigLocation)
+			})
+
+			It("Returns an error", func() {
+				_, err := Watch(&TestConfig{})
+
+				Expect(err).To(Not(BeNil()))
+			})
+		})
+
+		Context("When the config file is writable and watchable", func() {
+			It("Returns a `*Watcher` with no error", func() {
+				testConfig := &TestConfig{}
+				InitializeConfig(testConfig)
+
+				watcher, err := Watch(testConfig)
+				defer watcher.Stop()
+
+				Expect(err).To(BeNil())
+				Expect(watcher).To(Not(BeNil()))
+			})
+
+			It("Reloads the config when the watched file changes", func() {
+				testConfig := &TestConfig{}
+				InitializeConfig(testConfig)
+
+				var called []interface{}
+
+				watcher, err := Watch(testConfig, OnChange(func(old interface{}, new interface{}) {
+					called = append(called, new)
+				}))
+				Expect(err).To(BeNil())
+				defer watcher.Stop()
+
+				ioutil.WriteFile(file, []byte(`{"foo":"wxyz"}`), 0644)
+
+				Eventually(func() string {
+					current, ok := watcher.Get().(*TestConfig)
+					if !ok {
+						return ""
+					}
+
+					return current.Foo
+				}, "2s", "10ms").Should(Equal("wxyz"))
+
+				Expect(called).To(Not(BeEmpty()))
+			})
+
+			It("Still swaps in a reload that fails a `required` check, but reports it via `OnError`", func() {
+				testConfig := &TestConfig{}
+				InitializeConfig(testConfig)
+
+				var errs []error
+
+				watcher, err := Watch(testConfig, OnError(func(err error) {
+					errs = append(errs, err)
+				}))
+				Expect(err).To(BeNil())
+				defer watcher.Stop()
+
+				// `TestConfig.Required` is never set by this file, so every reload fails
+				// its `required` tag
+				ioutil.WriteFile(file, []byte(`{"foo":"wxyz"}`), 0644)
+
+				Eventually(func() string {
+					current, ok := watcher.Get().(*TestConfig)
+					if !ok {
+						return ""
+					}
+
+					return current.Foo
+				}, "2s", "10ms").Should(Equal("wxyz"))
+
+				Eventually(func() []error {
+					return errs
+				}, "2s", "10ms").Should(Not(BeEmpty()))
+
+				configErr, ok := errs[0].(*ConfigError)
+				Expect(ok).To(BeTrue())
+				Expect(configErr.Errors[0].Tag).To(Equal("required"))
+			})
+		})
+	})
+
+	Describe("`Get` method", func() {
+		It("Returns the value passed to `Watch` before any reload occurs", func() {
+			testConfig := &TestConfig{}
+			InitializeConfig(testConfig)
+
+			watcher, err := Watch(testConfig)
+			Expect(err).To(BeNil())
+			defer watcher.Stop()
+
+			Expect(watcher.Get()).To(BeIdenticalTo(testConfig))
+		})
+	})
+
+	Describe("`Stop` method", func() {
+		It("Stops watching without error", func() {
+			testConfig := &TestConfig{}
+			InitializeConfig(testConfig)
+
+			watcher, err := Watch(testConfig)
+			Expect(err).To(BeNil())
+
+			Expect(watcher.Stop()).To(BeNil())
+		})
+	})
+
+	Describe("`watchDebounce` constant", func() {
+		It("Is set to 200 milliseconds", func() {
+			Expect(watchDebounce).To(Equal(200 * time.Millisecond))
+		})
+	})
+})